@@ -0,0 +1,302 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+	"github.com/kubernetes/dashboard/src/app/backend/resource/deployment"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// DeploymentHandler serves the management surface for Deployments - create,
+// update, patch, delete, scale, pause/resume and rollback - that turns the
+// dashboard's Deployment view from observe-only into an actionable control
+// plane. It is installed onto the shared v1 web service alongside the
+// read-only list handlers.
+type DeploymentHandler struct {
+	client client.Interface
+}
+
+// NewDeploymentHandler returns a DeploymentHandler backed by client.
+func NewDeploymentHandler(client client.Interface) DeploymentHandler {
+	return DeploymentHandler{client: client}
+}
+
+// Install registers this handler's routes onto ws.
+func (h DeploymentHandler) Install(ws *restful.WebService) {
+	ws.Route(
+		ws.POST("/deployment").
+			To(h.handleCreate).
+			Reads(extensions.Deployment{}).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.PUT("/deployment/{namespace}/{name}").
+			To(h.handleUpdate).
+			Reads(extensions.Deployment{}).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.PATCH("/deployment/{namespace}/{name}").
+			To(h.handlePatch).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.GET("/deployment/{namespace}/watch").
+			To(h.handleWatch))
+	ws.Route(
+		ws.GET("/deployment/{namespace}/{name}/status").
+			To(h.handleStatus).
+			Writes(deployment.DeploymentStatus{}))
+	ws.Route(
+		ws.DELETE("/deployment/{namespace}/{name}").
+			To(h.handleDelete))
+	ws.Route(
+		ws.PUT("/deployment/{namespace}/{name}/scale/{replicas}").
+			To(h.handleScale).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.PUT("/deployment/{namespace}/{name}/pause").
+			To(h.handlePause).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.PUT("/deployment/{namespace}/{name}/resume").
+			To(h.handleResume).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.PUT("/deployment/{namespace}/{name}/rollback/{revision}").
+			To(h.handleRollback).
+			Writes(deployment.Deployment{}))
+	ws.Route(
+		ws.GET("/deployment/cachemetrics").
+			To(h.handleCacheMetrics).
+			Writes(deployment.CacheMetricsSnapshot{}))
+	ws.Route(
+		ws.GET("/deployment/cachemetrics/{namespace}").
+			To(h.handleCacheMetrics).
+			Writes(deployment.CacheMetricsSnapshot{}))
+}
+
+// handleCacheMetrics reports cache hit ratios and watch reconnect counts for
+// the Deployment/Pod/Event/ReplicaSet stores backing {namespace} (or every
+// namespace, if omitted), so the caching this handler's list endpoints rely on
+// is observable rather than taken on faith.
+func (h DeploymentHandler) handleCacheMetrics(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	if err := deployment.ValidateNamespace(h.client, namespace); err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+
+	nsQuery := common.NewNamespaceQuery([]string{namespace})
+	response.WriteEntity(deployment.CacheMetrics(h.client, nsQuery))
+}
+
+func (h DeploymentHandler) handleCreate(request *restful.Request, response *restful.Response) {
+	spec := new(extensions.Deployment)
+	if err := request.ReadEntity(spec); err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := deployment.CreateDeployment(h.client, spec)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteHeaderAndEntity(http.StatusCreated, result)
+}
+
+func (h DeploymentHandler) handleUpdate(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	spec := new(extensions.Deployment)
+	if err := request.ReadEntity(spec); err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := deployment.UpdateDeployment(h.client, namespace, name, spec)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+func (h DeploymentHandler) handlePatch(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	patchType := api.StrategicMergePatchType
+	if request.Request.Header.Get("Content-Type") == "application/json-patch+json" {
+		patchType = api.JSONPatchType
+	}
+
+	patchBytes, err := ioutil.ReadAll(request.Request.Body)
+	if err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := deployment.PatchDeployment(h.client, namespace, name, patchType, patchBytes)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// defaultStatusTimeout bounds how long handleStatus blocks waiting for a
+// Deployment's rollout to settle when the caller does not supply its own
+// timeoutSeconds query parameter.
+const defaultStatusTimeout = 2 * time.Minute
+
+// handleStatus blocks (up to a timeout) until the Deployment named {name} in
+// {namespace} reports StatusReady or StatusFailed, then writes its final
+// DeploymentStatus. Useful for CI gating a rollout and for the UI to render
+// an accurate badge without polling GetDeploymentList itself.
+func (h DeploymentHandler) handleStatus(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	timeout := defaultStatusTimeout
+	if raw := request.QueryParameter("timeoutSeconds"); raw != "" {
+		if seconds, err := strconv.Atoi(raw); err == nil {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	status, err := deployment.WaitForDeploymentStatus(h.client, namespace, name, timeout)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(status)
+}
+
+// handleWatch streams incremental DeploymentList changes for {namespace} to
+// the client as Server-Sent Events until the connection is closed. See
+// deployment.WatchDeploymentList for the cache-sharing behavior across
+// concurrent subscribers.
+func (h DeploymentHandler) handleWatch(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	if err := deployment.ValidateNamespace(h.client, namespace); err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+
+	nsQuery := common.NewNamespaceQuery([]string{namespace})
+	if err := deployment.WatchDeploymentList(response.ResponseWriter, request.Request, h.client, nsQuery); err != nil {
+		response.WriteError(http.StatusInternalServerError, err)
+	}
+}
+
+func (h DeploymentHandler) handleDelete(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+	cascade := request.QueryParameter("cascade") != "false"
+
+	if err := deployment.DeleteDeployment(h.client, namespace, name, cascade); err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteHeader(http.StatusNoContent)
+}
+
+func (h DeploymentHandler) handleScale(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	replicas, err := strconv.ParseInt(request.PathParameter("replicas"), 10, 32)
+	if err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := deployment.ScaleDeployment(h.client, namespace, name, int32(replicas))
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+func (h DeploymentHandler) handlePause(request *restful.Request, response *restful.Response) {
+	h.handlePauseResume(request, response, true)
+}
+
+func (h DeploymentHandler) handleResume(request *restful.Request, response *restful.Response) {
+	h.handlePauseResume(request, response, false)
+}
+
+func (h DeploymentHandler) handlePauseResume(request *restful.Request, response *restful.Response, paused bool) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	result, err := deployment.PauseResumeDeployment(h.client, namespace, name, paused)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+func (h DeploymentHandler) handleRollback(request *restful.Request, response *restful.Response) {
+	namespace := request.PathParameter("namespace")
+	name := request.PathParameter("name")
+
+	revision, err := strconv.ParseInt(request.PathParameter("revision"), 10, 64)
+	if err != nil {
+		response.WriteError(http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := deployment.RollbackDeployment(h.client, namespace, name, revision)
+	if err != nil {
+		writeDeploymentError(response, err)
+		return
+	}
+	response.WriteEntity(result)
+}
+
+// writeDeploymentError maps a deployment.Error returned by the CRUD package
+// to the matching HTTP status, falling back to 500 for anything else.
+func writeDeploymentError(response *restful.Response, err error) {
+	deploymentErr, ok := err.(*deployment.Error)
+	if !ok {
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	switch deploymentErr.Reason {
+	case deployment.ErrorReasonNotFound:
+		response.WriteError(http.StatusNotFound, deploymentErr)
+	case deployment.ErrorReasonConflict:
+		response.WriteError(http.StatusConflict, deploymentErr)
+	case deployment.ErrorReasonInvalid:
+		response.WriteError(http.StatusUnprocessableEntity, deploymentErr)
+	default:
+		response.WriteError(http.StatusInternalServerError, deploymentErr)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"net/http"
+
+	restful "github.com/emicklei/go-restful"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// ResourceKindHandler serves the generic list endpoint for any
+// common.ResourceKindHandler registered via common.RegisterKind, so a kind
+// added after the dashboard ships (a CRD, Argo Rollouts, anything else with a
+// handler) shows up at /resourcekind/{kind}/... without its own hand-written
+// route.
+type ResourceKindHandler struct {
+	client client.Interface
+}
+
+// NewResourceKindHandler returns a ResourceKindHandler backed by client.
+func NewResourceKindHandler(client client.Interface) ResourceKindHandler {
+	return ResourceKindHandler{client: client}
+}
+
+// Install registers this handler's routes onto ws.
+func (h ResourceKindHandler) Install(ws *restful.WebService) {
+	ws.Route(
+		ws.GET("/resourcekind/{kind}").
+			To(h.handleList).
+			Writes(common.CommonListItemList{}))
+	ws.Route(
+		ws.GET("/resourcekind/{kind}/{namespace}").
+			To(h.handleList).
+			Writes(common.CommonListItemList{}))
+}
+
+// handleList looks up the common.ResourceKindHandler registered for {kind}
+// and lists it, 404ing if no handler has registered that kind.
+func (h ResourceKindHandler) handleList(request *restful.Request, response *restful.Response) {
+	kind := common.ResourceKind(request.PathParameter("kind"))
+
+	kindHandler, ok := common.KindHandler(kind)
+	if !ok {
+		response.WriteErrorString(http.StatusNotFound, "no resource kind handler registered for "+string(kind))
+		return
+	}
+
+	namespace := request.PathParameter("namespace")
+	nsQuery := common.NewNamespaceQuery([]string{namespace})
+
+	// This endpoint has no sort/filter/paginate query parameters of its own
+	// yet, so every kind is listed with the zero-value (unsorted, unfiltered)
+	// DataSelectQuery.
+	result, err := common.ListCommonItems(h.client, kindHandler, nsQuery, &common.DataSelectQuery{})
+	if err != nil {
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+	response.WriteEntity(result)
+}
@@ -0,0 +1,139 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// ResourceKindHandler lets a Kubernetes resource kind plug into the
+// dashboard's generic list views without editing core list code. Before this
+// existed, adding a new kind (e.g. apps/v1 StatefulSet, a CRD, Argo Rollouts)
+// meant forking deploymentlist.go-shaped code; now a package only has to
+// implement this interface and call RegisterKind.
+type ResourceKindHandler interface {
+	// Kind is the TypeMeta.Kind this handler produces, e.g.
+	// ResourceKindDeployment or a ResourceKind built from a CRD's plural
+	// resource name.
+	Kind() ResourceKind
+
+	// ListChannel returns the shared, informer-backed ResourceStore of raw API
+	// objects for nsQuery, starting it on first use.
+	ListChannel(client client.Interface, nsQuery *NamespaceQuery) *ResourceStore
+
+	// Convert turns the raw objects read from ListChannel, together with the
+	// cluster's Pods and Events, into the list items this kind contributes to
+	// a combined dashboard view.
+	Convert(raw []interface{}, pods []api.Pod, events []api.Event, dsQuery *DataSelectQuery) []CommonListItem
+}
+
+// CommonListItem is the generic list-view shape every ResourceKindHandler
+// converts its raw API objects into, so the HTTP layer can render a combined
+// list across kinds without a Go type specific to any one of them.
+type CommonListItem struct {
+	ObjectMeta ObjectMeta `json:"objectMeta"`
+	TypeMeta   TypeMeta   `json:"typeMeta"`
+
+	// Aggregate information about Pods belonging to this resource, when the
+	// kind has Pods at all.
+	Pods PodInfo `json:"pods"`
+
+	// Container images of the resource, when applicable.
+	ContainerImages []string `json:"containerImages,omitempty"`
+}
+
+// CommonListItemList is the generic list response for a registered resource
+// kind - analogous to a kind's own hand-written list type (e.g.
+// deployment.DeploymentList) but usable by the HTTP layer without knowing the
+// concrete kind ahead of time.
+type CommonListItemList struct {
+	ListMeta ListMeta         `json:"listMeta"`
+	Items    []CommonListItem `json:"items"`
+}
+
+var kindRegistry = struct {
+	mux      sync.RWMutex
+	handlers map[ResourceKind]ResourceKindHandler
+}{handlers: make(map[ResourceKind]ResourceKindHandler)}
+
+// RegisterKind adds handler to the registry, keyed by handler.Kind(). It
+// panics if a handler for the same kind is already registered, since that
+// almost always means two packages' init() functions collided.
+func RegisterKind(handler ResourceKindHandler) {
+	kindRegistry.mux.Lock()
+	defer kindRegistry.mux.Unlock()
+
+	kind := handler.Kind()
+	if _, exists := kindRegistry.handlers[kind]; exists {
+		panic(fmt.Sprintf("a ResourceKindHandler for kind %q is already registered", kind))
+	}
+	kindRegistry.handlers[kind] = handler
+}
+
+// KindHandler looks up the ResourceKindHandler registered for kind, if any.
+func KindHandler(kind ResourceKind) (ResourceKindHandler, bool) {
+	kindRegistry.mux.RLock()
+	defer kindRegistry.mux.RUnlock()
+
+	handler, ok := kindRegistry.handlers[kind]
+	return handler, ok
+}
+
+// RegisteredKinds returns the kinds of every currently registered handler, in
+// no particular order.
+func RegisteredKinds() []ResourceKind {
+	kindRegistry.mux.RLock()
+	defer kindRegistry.mux.RUnlock()
+
+	kinds := make([]ResourceKind, 0, len(kindRegistry.handlers))
+	for kind := range kindRegistry.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// ListCommonItems drives a registered ResourceKindHandler through its
+// List/Convert cycle: read the kind's own ResourceStore plus the cluster's
+// shared Pod and Event stores, then hand everything to Convert. This is the
+// one piece of dispatch code the HTTP layer needs regardless of how many
+// kinds end up registered.
+func ListCommonItems(client client.Interface, handler ResourceKindHandler, nsQuery *NamespaceQuery,
+	dsQuery *DataSelectQuery) (*CommonListItemList, error) {
+
+	rawItems, err := handler.ListChannel(client, nsQuery).List()
+	if err != nil {
+		return nil, err
+	}
+
+	rawPods, err := SharedPodStore(client, nsQuery).List()
+	if err != nil {
+		return nil, err
+	}
+
+	rawEvents, err := SharedEventStore(client, nsQuery).List()
+	if err != nil {
+		return nil, err
+	}
+
+	items := handler.Convert(rawItems, ToPods(rawPods), ToEvents(rawEvents), dsQuery)
+	return &CommonListItemList{
+		ListMeta: ListMeta{TotalItems: len(items)},
+		Items:    items,
+	}, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import "k8s.io/kubernetes/pkg/api"
+
+// PodsByNamespace buckets a Pod list by namespace once, so a list view that
+// calls FilterNamespacedPodsBySelector once per item (once per Deployment,
+// once per registered CommonListItem, ...) looks its namespace up in the
+// index instead of rescanning every Pod in the cluster for each item.
+type PodsByNamespace map[string][]api.Pod
+
+// NewPodsByNamespace indexes pods by namespace.
+func NewPodsByNamespace(pods []api.Pod) PodsByNamespace {
+	index := make(PodsByNamespace)
+	for _, pod := range pods {
+		ns := pod.ObjectMeta.Namespace
+		index[ns] = append(index[ns], pod)
+	}
+	return index
+}
+
+// Get returns the Pods indexed under namespace, or nil if there are none.
+func (idx PodsByNamespace) Get(namespace string) []api.Pod {
+	return idx[namespace]
+}
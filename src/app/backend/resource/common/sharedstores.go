@@ -0,0 +1,156 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sync"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/cache"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// sharedStoreKey identifies one memoized shared ResourceStore by both the
+// client that backs it and its namespace scope. Keying by namespace alone
+// would make two distinct clients requesting the same scope - two fake
+// clientsets in two tests running in the same binary, or a future
+// multi-cluster dashboard - silently share the first caller's client and
+// data.
+type sharedStoreKey struct {
+	client client.Interface
+	ns     string
+}
+
+// sharedStores memoizes one Pod and one Event ResourceStore per
+// sharedStoreKey, so that every ResourceKindHandler - and any package that
+// needs cluster-wide Pods or Events to augment its own list view - reads from
+// the same Reflector instead of each opening its own List/Watch against the
+// apiserver.
+var sharedStores = struct {
+	mux           sync.Mutex
+	podsByScope   map[sharedStoreKey]*ResourceStore
+	eventsByScope map[sharedStoreKey]*ResourceStore
+}{
+	podsByScope:   make(map[sharedStoreKey]*ResourceStore),
+	eventsByScope: make(map[sharedStoreKey]*ResourceStore),
+}
+
+// SharedPodStore returns the cluster's shared Pod ResourceStore for client
+// and nsQuery, starting its Reflector the first time the pair is requested.
+func SharedPodStore(client client.Interface, nsQuery *NamespaceQuery) *ResourceStore {
+	sharedStores.mux.Lock()
+	defer sharedStores.mux.Unlock()
+
+	ns := nsQuery.ToRequestParam()
+	key := sharedStoreKey{client: client, ns: ns}
+	if store, ok := sharedStores.podsByScope[key]; ok {
+		return store
+	}
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+			return client.Pods(ns).List(options)
+		},
+		WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+			return client.Pods(ns).Watch(options)
+		},
+	}
+	store := NewResourceStore(listWatch, &api.Pod{}, DefaultResyncPeriod, func() ([]interface{}, error) {
+		list, err := client.Pods(ns).List(api.ListOptions{
+			LabelSelector: labels.Everything(),
+			FieldSelector: fields.Everything(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, 0, len(list.Items))
+		for i := range list.Items {
+			items = append(items, &list.Items[i])
+		}
+		return items, nil
+	})
+	store.Run(wait.NeverStop)
+	sharedStores.podsByScope[key] = store
+	return store
+}
+
+// SharedEventStore returns the cluster's shared Event ResourceStore for
+// client and nsQuery, analogous to SharedPodStore.
+func SharedEventStore(client client.Interface, nsQuery *NamespaceQuery) *ResourceStore {
+	sharedStores.mux.Lock()
+	defer sharedStores.mux.Unlock()
+
+	ns := nsQuery.ToRequestParam()
+	key := sharedStoreKey{client: client, ns: ns}
+	if store, ok := sharedStores.eventsByScope[key]; ok {
+		return store
+	}
+
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+			return client.Events(ns).List(options)
+		},
+		WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+			return client.Events(ns).Watch(options)
+		},
+	}
+	store := NewResourceStore(listWatch, &api.Event{}, DefaultResyncPeriod, func() ([]interface{}, error) {
+		list, err := client.Events(ns).List(api.ListOptions{
+			LabelSelector: labels.Everything(),
+			FieldSelector: fields.Everything(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, 0, len(list.Items))
+		for i := range list.Items {
+			items = append(items, &list.Items[i])
+		}
+		return items, nil
+	})
+	store.Run(wait.NeverStop)
+	sharedStores.eventsByScope[key] = store
+	return store
+}
+
+// ToPods converts the raw interface{} items returned by a Pod ResourceStore
+// into a []api.Pod, skipping anything unexpected instead of panicking.
+func ToPods(items []interface{}) []api.Pod {
+	result := make([]api.Pod, 0, len(items))
+	for _, item := range items {
+		if p, ok := item.(*api.Pod); ok {
+			result = append(result, *p)
+		}
+	}
+	return result
+}
+
+// ToEvents converts the raw interface{} items returned by an Event
+// ResourceStore into a []api.Event, skipping anything unexpected instead of
+// panicking.
+func ToEvents(items []interface{}) []api.Event {
+	result := make([]api.Event, 0, len(items))
+	for _, item := range items {
+		if e, ok := item.(*api.Event); ok {
+			result = append(result, *e)
+		}
+	}
+	return result
+}
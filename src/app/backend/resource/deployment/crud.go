@@ -0,0 +1,162 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// CreateDeployment creates spec in the cluster and returns the presentation
+// layer view of the Deployment the apiserver created.
+func CreateDeployment(client client.Interface, spec *extensions.Deployment) (*Deployment, error) {
+	log.Printf("Creating deployment %s in namespace %s", spec.Name, spec.Namespace)
+
+	created, err := client.Extensions().Deployments(spec.Namespace).Create(spec)
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, created), nil
+}
+
+// UpdateDeployment replaces the Deployment named name in namespace with spec
+// and returns the presentation layer view of the result. spec must carry the
+// ResourceVersion of the object being updated, or the apiserver rejects the
+// update with a Conflict error.
+func UpdateDeployment(client client.Interface, namespace, name string,
+	spec *extensions.Deployment) (*Deployment, error) {
+	log.Printf("Updating deployment %s in namespace %s", name, namespace)
+
+	spec.Name = name
+	updated, err := client.Extensions().Deployments(namespace).Update(spec)
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, updated), nil
+}
+
+// PatchDeployment applies patchBytes, of the given patchType (strategic merge
+// or JSON patch), to the Deployment named name in namespace and returns the
+// presentation layer view of the result.
+func PatchDeployment(client client.Interface, namespace, name string, patchType api.PatchType,
+	patchBytes []byte) (*Deployment, error) {
+	log.Printf("Patching deployment %s in namespace %s", name, namespace)
+
+	patched, err := client.Extensions().Deployments(namespace).Patch(name, patchType, patchBytes)
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, patched), nil
+}
+
+// DeleteDeployment deletes the Deployment named name in namespace. When
+// cascade is true the Deployment's ReplicaSets and Pods are deleted along
+// with it; when false, only the Deployment object itself is removed and its
+// ReplicaSets are orphaned.
+func DeleteDeployment(client client.Interface, namespace, name string, cascade bool) error {
+	log.Printf("Deleting deployment %s from namespace %s", name, namespace)
+
+	orphan := !cascade
+	options := &api.DeleteOptions{OrphanDependents: &orphan}
+
+	if err := client.Extensions().Deployments(namespace).Delete(name, options); err != nil {
+		return toDeploymentError(err)
+	}
+	return nil
+}
+
+// ScaleDeployment updates the Deployment named name in namespace to run
+// replicas pods, via a strategic merge patch of spec.replicas so a concurrent
+// edit to the rest of the spec is not clobbered.
+func ScaleDeployment(client client.Interface, namespace, name string, replicas int32) (*Deployment, error) {
+	log.Printf("Scaling deployment %s in namespace %s to %d replicas", name, namespace, replicas)
+
+	patch := fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas)
+	patched, err := client.Extensions().Deployments(namespace).Patch(name, api.StrategicMergePatchType,
+		[]byte(patch))
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, patched), nil
+}
+
+// PauseResumeDeployment pauses or resumes the rollout of the Deployment named
+// name in namespace by patching spec.paused. While paused, the Deployment
+// controller stops reconciling PodTemplateSpec changes into a new ReplicaSet
+// until it is resumed again.
+func PauseResumeDeployment(client client.Interface, namespace, name string, paused bool) (*Deployment, error) {
+	log.Printf("Setting paused=%t on deployment %s in namespace %s", paused, name, namespace)
+
+	patch := fmt.Sprintf(`{"spec":{"paused":%t}}`, paused)
+	patched, err := client.Extensions().Deployments(namespace).Patch(name, api.StrategicMergePatchType,
+		[]byte(patch))
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, patched), nil
+}
+
+// RollbackDeployment rolls the Deployment named name in namespace back to
+// toRevision (or the previous revision when toRevision is 0) by submitting a
+// DeploymentRollback request and re-fetching the Deployment right after it is
+// accepted. The deployment controller replays the matching ReplicaSet's
+// PodTemplateSpec asynchronously, so the returned Deployment can still show
+// the pre-rollback template.
+func RollbackDeployment(client client.Interface, namespace, name string, toRevision int64) (*Deployment, error) {
+	log.Printf("Rolling back deployment %s in namespace %s to revision %d", name, namespace, toRevision)
+
+	rollback := &extensions.DeploymentRollback{
+		Name:       name,
+		RollbackTo: extensions.RollbackConfig{Revision: toRevision},
+	}
+
+	if err := client.Extensions().Deployments(namespace).Rollback(rollback); err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	updated, err := client.Extensions().Deployments(namespace).Get(name)
+	if err != nil {
+		return nil, toDeploymentError(err)
+	}
+
+	return toDeployment(client, updated), nil
+}
+
+// toDeployment converts a raw Kubernetes Deployment into the presentation
+// layer view this package's write operations return. Unlike
+// CreateDeploymentList, callers of the CRUD operations above get back the
+// object they just mutated immediately, without pod/event augmentation, but
+// with the same rollout Status a list view would compute for it.
+func toDeployment(client client.Interface, deployment *extensions.Deployment) *Deployment {
+	nsQuery := common.NewNamespaceQuery([]string{deployment.ObjectMeta.Namespace})
+
+	return &Deployment{
+		ObjectMeta:      common.NewObjectMeta(deployment.ObjectMeta),
+		TypeMeta:        common.NewTypeMeta(common.ResourceKindDeployment),
+		ContainerImages: common.GetContainerImages(&deployment.Spec.Template.Spec),
+		Status:          deploymentStatus(client, deployment, nsQuery),
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	core "k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+// patchFor returns the bytes of the first Patch action testclient recorded
+// against name, failing the test if there isn't one.
+func patchFor(t *testing.T, fake *testclient.Fake, name string) []byte {
+	t.Helper()
+	for _, action := range fake.Actions() {
+		patchAction, ok := action.(core.PatchAction)
+		if !ok || patchAction.GetName() != name {
+			continue
+		}
+		return patchAction.GetPatch()
+	}
+	t.Fatalf("no patch action recorded for %q", name)
+	return nil
+}
+
+func TestScaleDeployment(t *testing.T) {
+	fake := testclient.NewSimpleFake(&extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{Name: "frontend", Namespace: "default"},
+	})
+
+	if _, err := ScaleDeployment(fake, "default", "frontend", 5); err != nil {
+		t.Fatalf("ScaleDeployment() error = %v", err)
+	}
+
+	patch := string(patchFor(t, fake, "frontend"))
+	if !strings.Contains(patch, `"replicas":5`) {
+		t.Errorf("patch = %s, want it to set replicas to 5", patch)
+	}
+}
+
+func TestPauseResumeDeployment(t *testing.T) {
+	cases := []struct {
+		paused bool
+		want   string
+	}{
+		{paused: true, want: `"paused":true`},
+		{paused: false, want: `"paused":false`},
+	}
+
+	for _, c := range cases {
+		fake := testclient.NewSimpleFake(&extensions.Deployment{
+			ObjectMeta: api.ObjectMeta{Name: "frontend", Namespace: "default"},
+		})
+
+		if _, err := PauseResumeDeployment(fake, "default", "frontend", c.paused); err != nil {
+			t.Fatalf("PauseResumeDeployment(paused=%t) error = %v", c.paused, err)
+		}
+
+		patch := string(patchFor(t, fake, "frontend"))
+		if !strings.Contains(patch, c.want) {
+			t.Errorf("patch = %s, want it to contain %s", patch, c.want)
+		}
+	}
+}
+
+func TestDeleteDeploymentCascadeAndOrphan(t *testing.T) {
+	cases := []struct {
+		name    string
+		cascade bool
+	}{
+		{name: "cascade deletes the Deployment", cascade: true},
+		{name: "orphan deletes the Deployment", cascade: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := testclient.NewSimpleFake(&extensions.Deployment{
+				ObjectMeta: api.ObjectMeta{Name: "frontend", Namespace: "default"},
+			})
+
+			if err := DeleteDeployment(fake, "default", "frontend", c.cascade); err != nil {
+				t.Fatalf("DeleteDeployment(cascade=%t) error = %v", c.cascade, err)
+			}
+
+			if _, err := fake.Extensions().Deployments("default").Get("frontend"); err == nil {
+				t.Fatalf("deployment still exists after DeleteDeployment(cascade=%t)", c.cascade)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"sync"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/cache"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// cacheKey identifies one memoized ResourceStore by both the client that
+// backs it and its namespace scope (an empty namespace string means "all
+// namespaces"). Keying by namespace alone would make two distinct clients
+// requesting the same scope - two fake clientsets in two tests running in
+// the same binary, or a future multi-cluster dashboard - silently share the
+// first caller's client and data.
+type cacheKey struct {
+	client client.Interface
+	ns     string
+}
+
+// deploymentCacheFactory lazily creates and memoizes one informer-backed
+// ResourceStore per cacheKey, so that repeated dashboard requests for the
+// same client and scope share a single Reflector instead of each opening
+// their own List/Watch against the apiserver.
+type deploymentCacheFactory struct {
+	mux                sync.Mutex
+	byScope            map[cacheKey]*common.ResourceStore
+	replicaSetsByScope map[cacheKey]*common.ResourceStore
+}
+
+var cacheFactory = &deploymentCacheFactory{
+	byScope:            make(map[cacheKey]*common.ResourceStore),
+	replicaSetsByScope: make(map[cacheKey]*common.ResourceStore),
+}
+
+// storeFor returns the ResourceStore for the given cacheKey, creating and
+// starting it on first use. Callers must hold cacheFactory.mux.
+func storeFor(scope map[cacheKey]*common.ResourceStore, key cacheKey,
+	newStore func() *common.ResourceStore) *common.ResourceStore {
+
+	if existing, ok := scope[key]; ok {
+		return existing
+	}
+	store := newStore()
+	store.Run(wait.NeverStop)
+	scope[key] = store
+	return store
+}
+
+// getDeploymentStore returns the shared Deployment ResourceStore for client
+// and nsQuery, starting its Reflector the first time the pair is requested.
+func getDeploymentStore(client client.Interface, nsQuery *common.NamespaceQuery) *common.ResourceStore {
+	cacheFactory.mux.Lock()
+	defer cacheFactory.mux.Unlock()
+
+	ns := nsQuery.ToRequestParam()
+	key := cacheKey{client: client, ns: ns}
+	return storeFor(cacheFactory.byScope, key, func() *common.ResourceStore {
+		listWatch := &cache.ListWatch{
+			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+				return client.Extensions().Deployments(ns).List(options)
+			},
+			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+				return client.Extensions().Deployments(ns).Watch(options)
+			},
+		}
+		return common.NewResourceStore(listWatch, &extensions.Deployment{}, common.DefaultResyncPeriod,
+			func() ([]interface{}, error) {
+				list, err := client.Extensions().Deployments(ns).List(api.ListOptions{
+					LabelSelector: labels.Everything(),
+					FieldSelector: fields.Everything(),
+				})
+				if err != nil {
+					return nil, err
+				}
+				items := make([]interface{}, 0, len(list.Items))
+				for i := range list.Items {
+					items = append(items, &list.Items[i])
+				}
+				return items, nil
+			})
+	})
+}
+
+// getPodStore returns the cluster's shared Pod ResourceStore for nsQuery. It
+// is kept as a thin alias so the rest of this package does not need to know
+// that Pods and Events are now cached once, centrally, in common rather than
+// per resource-kind package.
+func getPodStore(client client.Interface, nsQuery *common.NamespaceQuery) *common.ResourceStore {
+	return common.SharedPodStore(client, nsQuery)
+}
+
+// getEventStore returns the cluster's shared Event ResourceStore for nsQuery.
+func getEventStore(client client.Interface, nsQuery *common.NamespaceQuery) *common.ResourceStore {
+	return common.SharedEventStore(client, nsQuery)
+}
+
+// getReplicaSetStore returns the shared ReplicaSet ResourceStore for client
+// and nsQuery, used to find old ReplicaSets still lingering behind a
+// Deployment's rollout.
+func getReplicaSetStore(client client.Interface, nsQuery *common.NamespaceQuery) *common.ResourceStore {
+	cacheFactory.mux.Lock()
+	defer cacheFactory.mux.Unlock()
+
+	ns := nsQuery.ToRequestParam()
+	key := cacheKey{client: client, ns: ns}
+	return storeFor(cacheFactory.replicaSetsByScope, key, func() *common.ResourceStore {
+		listWatch := &cache.ListWatch{
+			ListFunc: func(options api.ListOptions) (runtime.Object, error) {
+				return client.Extensions().ReplicaSets(ns).List(options)
+			},
+			WatchFunc: func(options api.ListOptions) (watch.Interface, error) {
+				return client.Extensions().ReplicaSets(ns).Watch(options)
+			},
+		}
+		return common.NewResourceStore(listWatch, &extensions.ReplicaSet{}, common.DefaultResyncPeriod,
+			func() ([]interface{}, error) {
+				list, err := client.Extensions().ReplicaSets(ns).List(api.ListOptions{
+					LabelSelector: labels.Everything(),
+					FieldSelector: fields.Everything(),
+				})
+				if err != nil {
+					return nil, err
+				}
+				items := make([]interface{}, 0, len(list.Items))
+				for i := range list.Items {
+					items = append(items, &list.Items[i])
+				}
+				return items, nil
+			})
+	})
+}
+
+// CacheMetricsSnapshot reports the current cache hit/miss/reconnect counters
+// for the stores backing a Deployment list, so cache effectiveness is
+// observable instead of only inferred from apiserver request volume.
+type CacheMetricsSnapshot struct {
+	Deployments *common.CacheMetrics `json:"deployments"`
+	Pods        *common.CacheMetrics `json:"pods"`
+	Events      *common.CacheMetrics `json:"events"`
+	ReplicaSets *common.CacheMetrics `json:"replicaSets"`
+}
+
+// CacheMetrics returns a CacheMetricsSnapshot for nsQuery's scope, creating
+// any of the underlying stores that have not been requested yet - the same
+// lazy-start behavior as GetDeploymentList.
+func CacheMetrics(client client.Interface, nsQuery *common.NamespaceQuery) CacheMetricsSnapshot {
+	return CacheMetricsSnapshot{
+		Deployments: getDeploymentStore(client, nsQuery).Metrics(),
+		Pods:        getPodStore(client, nsQuery).Metrics(),
+		Events:      getEventStore(client, nsQuery).Metrics(),
+		ReplicaSets: getReplicaSetStore(client, nsQuery).Metrics(),
+	}
+}
+
+// toDeployments converts the raw interface{} items returned by a ResourceStore
+// into a []extensions.Deployment, skipping anything unexpected instead of
+// panicking, since the store holds whatever the Reflector last saw.
+func toDeployments(items []interface{}) []extensions.Deployment {
+	result := make([]extensions.Deployment, 0, len(items))
+	for _, item := range items {
+		if d, ok := item.(*extensions.Deployment); ok {
+			result = append(result, *d)
+		}
+	}
+	return result
+}
+
+func toReplicaSets(items []interface{}) []extensions.ReplicaSet {
+	result := make([]extensions.ReplicaSet, 0, len(items))
+	for _, item := range items {
+		if rs, ok := item.(*extensions.ReplicaSet); ok {
+			result = append(result, *rs)
+		}
+	}
+	return result
+}
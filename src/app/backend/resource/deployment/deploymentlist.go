@@ -46,29 +46,40 @@ type Deployment struct {
 
 	// Container images of the Deployment.
 	ContainerImages []string `json:"containerImages"`
+
+	// Status is the rollout readiness of the Deployment, computed with Helm's
+	// resource readiness rules rather than inferred from Pods alone.
+	Status DeploymentStatus `json:"status"`
 }
 
-// GetDeploymentList returns a list of all Deployments in the cluster.
+// GetDeploymentList returns a list of all Deployments in the cluster, served
+// from the shared informer caches kept warm by getDeploymentStore/getPodStore/
+// getEventStore instead of issuing a fresh List against the apiserver on every
+// call.
 func GetDeploymentList(client client.Interface, nsQuery *common.NamespaceQuery,
 	dsQuery *common.DataSelectQuery) (*DeploymentList, error) {
 	log.Printf("Getting list of all deployments in the cluster")
 
-	channels := &common.ResourceChannels{
-		DeploymentList: common.GetDeploymentListChannel(client.Extensions(), nsQuery, 1),
-		PodList:        common.GetPodListChannel(client, nsQuery, 1),
-		EventList:      common.GetEventListChannel(client, nsQuery, 1),
-	}
+	deploymentStore := getDeploymentStore(client, nsQuery)
+	podStore := getPodStore(client, nsQuery)
+	eventStore := getEventStore(client, nsQuery)
+	replicaSetStore := getReplicaSetStore(client, nsQuery)
 
-	return GetDeploymentListFromChannels(channels, dsQuery)
+	return GetDeploymentListFromChannels(deploymentStore, podStore, eventStore, replicaSetStore, dsQuery)
 }
 
-// GetDeploymentList returns a list of all Deployments in the cluster
-// reading required resource list once from the channels.
-func GetDeploymentListFromChannels(channels *common.ResourceChannels,
+// GetDeploymentListFromChannels returns a list of all Deployments in the
+// cluster, reading the Deployment/Pod/Event/ReplicaSet ResourceStores
+// synchronously. The name is kept for API continuity with the previous
+// channel-based signature, but these reads are served entirely out of the
+// local cache - no channel or apiserver round trip is involved unless a
+// store has not synced yet, in which case it transparently falls back to a
+// direct List.
+func GetDeploymentListFromChannels(deploymentStore, podStore, eventStore, replicaSetStore *common.ResourceStore,
 	dsQuery *common.DataSelectQuery) (*DeploymentList, error) {
 
-	deployments := <-channels.DeploymentList.List
-	if err := <-channels.DeploymentList.Error; err != nil {
+	rawDeployments, err := deploymentStore.List()
+	if err != nil {
 		statusErr, ok := err.(*k8serrors.StatusError)
 		if ok && statusErr.ErrStatus.Reason == "NotFound" {
 			// NotFound - this means that the server does not support Deployment objects, which
@@ -81,23 +92,29 @@ func GetDeploymentListFromChannels(channels *common.ResourceChannels,
 		return nil, err
 	}
 
-	pods := <-channels.PodList.List
-	if err := <-channels.PodList.Error; err != nil {
+	rawPods, err := podStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	rawEvents, err := eventStore.List()
+	if err != nil {
 		return nil, err
 	}
 
-	events := <-channels.EventList.List
-	if err := <-channels.EventList.Error; err != nil {
+	rawReplicaSets, err := replicaSetStore.List()
+	if err != nil {
 		return nil, err
 	}
 
-	return CreateDeploymentList(deployments.Items, pods.Items, events.Items, dsQuery), nil
+	return CreateDeploymentList(toDeployments(rawDeployments), common.ToPods(rawPods), common.ToEvents(rawEvents),
+		toReplicaSets(rawReplicaSets), dsQuery), nil
 }
 
 // CreateDeploymentList returns a list of all Deployment model objects in the cluster, based on all
 // Kubernetes Deployment API objects.
 func CreateDeploymentList(deployments []extensions.Deployment, pods []api.Pod,
-	events []api.Event, dsQuery *common.DataSelectQuery) *DeploymentList {
+	events []api.Event, replicaSets []extensions.ReplicaSet, dsQuery *common.DataSelectQuery) *DeploymentList {
 
 	deploymentList := &DeploymentList{
 		Deployments: make([]Deployment, 0),
@@ -105,11 +122,12 @@ func CreateDeploymentList(deployments []extensions.Deployment, pods []api.Pod,
 	}
 
 	deployments = fromCells(common.GenericDataSelect(toCells(deployments), dsQuery))
+	podIndex := common.NewPodsByNamespace(pods)
 
 	for _, deployment := range deployments {
 
-		matchingPods := common.FilterNamespacedPodsBySelector(pods, deployment.ObjectMeta.Namespace,
-			deployment.Spec.Selector.MatchLabels)
+		matchingPods := common.FilterNamespacedPodsBySelector(podIndex.Get(deployment.ObjectMeta.Namespace),
+			deployment.ObjectMeta.Namespace, deployment.Spec.Selector.MatchLabels)
 		podInfo := common.GetPodInfo(deployment.Status.Replicas, deployment.Spec.Replicas,
 			matchingPods)
 		podInfo.Warnings = event.GetPodsEventWarnings(events, matchingPods)
@@ -120,6 +138,7 @@ func CreateDeploymentList(deployments []extensions.Deployment, pods []api.Pod,
 				TypeMeta:        common.NewTypeMeta(common.ResourceKindDeployment),
 				ContainerImages: common.GetContainerImages(&deployment.Spec.Template.Spec),
 				Pods:            podInfo,
+				Status:          GetDeploymentStatus(&deployment, oldReplicaSetsFor(&deployment, replicaSets)),
 			})
 	}
 
@@ -0,0 +1,309 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+	"github.com/kubernetes/dashboard/src/app/backend/resource/event"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/watch"
+)
+
+// watchRetryBackoff is how long run waits before retrying a failed List or
+// Watch call, so an apiserver outage or auth hiccup degrades into periodic
+// retries instead of a tight busy-loop.
+const watchRetryBackoff = 5 * time.Second
+
+// DeploymentWatchEvent is a single incremental change to the augmented
+// Deployment view, as emitted over a WatchDeploymentList subscription.
+type DeploymentWatchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object *Deployment     `json:"object"`
+}
+
+// deploymentWatchHub fans a single underlying apiserver Deployment watch out
+// to every subscriber registered for the same namespace scope, so that N
+// browser tabs watching the same scope share one Watch connection instead of
+// each opening its own. Its background run goroutine is stopped once the
+// last subscriber disconnects, rather than kept alive for the life of the
+// process - namespace is attacker-controlled path input, so a hub that never
+// stops would leak one goroutine and one live apiserver watch per distinct
+// string ever requested.
+type deploymentWatchHub struct {
+	mux         sync.Mutex
+	subscribers map[chan DeploymentWatchEvent]bool
+	started     bool
+	stopCh      chan struct{}
+}
+
+// watchHubKey identifies one memoized deploymentWatchHub by both the client
+// that backs it and its namespace scope, for the same reason cacheKey and
+// sharedStoreKey do: keying by namespace alone would let two distinct
+// clients requesting the same scope - two fake clientsets in two tests
+// running in the same binary, or a future multi-cluster dashboard - silently
+// share the first caller's client and watch events.
+type watchHubKey struct {
+	client client.Interface
+	ns     string
+}
+
+var watchHubs = struct {
+	mux   sync.Mutex
+	byKey map[watchHubKey]*deploymentWatchHub
+}{byKey: make(map[watchHubKey]*deploymentWatchHub)}
+
+// subscribeToWatchHub returns a channel subscribed to the watch hub for
+// client and nsQuery, creating the hub and starting its background watch
+// loop on first use. Subscribing is serialized against
+// unsubscribeFromWatchHub on the same watchHubs lock, so a new subscriber
+// can never join a hub that is concurrently stopping because its previous
+// last subscriber just left.
+func subscribeToWatchHub(client client.Interface, nsQuery *common.NamespaceQuery) (*deploymentWatchHub, chan DeploymentWatchEvent) {
+	watchHubs.mux.Lock()
+	defer watchHubs.mux.Unlock()
+
+	ns := nsQuery.ToRequestParam()
+	key := watchHubKey{client: client, ns: ns}
+	hub, ok := watchHubs.byKey[key]
+	if !ok {
+		hub = &deploymentWatchHub{subscribers: make(map[chan DeploymentWatchEvent]bool), stopCh: make(chan struct{})}
+		watchHubs.byKey[key] = hub
+	}
+	if !hub.started {
+		hub.started = true
+		go hub.run(client, nsQuery, ns)
+	}
+
+	ch := make(chan DeploymentWatchEvent, 16)
+	hub.mux.Lock()
+	hub.subscribers[ch] = true
+	hub.mux.Unlock()
+	return hub, ch
+}
+
+// unsubscribeFromWatchHub removes ch from hub's subscribers. Once hub's last
+// subscriber has disconnected, it also drops hub from watchHubs and stops its
+// run goroutine, so a later subscribeToWatchHub call for the same client and
+// ns starts a fresh hub instead of resuming a stopped one.
+func unsubscribeFromWatchHub(client client.Interface, ns string, hub *deploymentWatchHub, ch chan DeploymentWatchEvent) {
+	watchHubs.mux.Lock()
+	defer watchHubs.mux.Unlock()
+
+	hub.mux.Lock()
+	delete(hub.subscribers, ch)
+	empty := len(hub.subscribers) == 0
+	hub.mux.Unlock()
+	close(ch)
+
+	if !empty {
+		return
+	}
+
+	key := watchHubKey{client: client, ns: ns}
+	if current, ok := watchHubs.byKey[key]; ok && current == hub {
+		delete(watchHubs.byKey, key)
+	}
+	close(hub.stopCh)
+}
+
+func (h *deploymentWatchHub) broadcast(deploymentEvent DeploymentWatchEvent) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	for ch := range h.subscribers {
+		select {
+		case ch <- deploymentEvent:
+		default:
+			// Slow subscriber - drop the event rather than block the whole hub.
+			log.Printf("Dropping deployment watch event for a slow subscriber")
+		}
+	}
+}
+
+// run watches Deployments in ns until h.stopCh is closed (the last subscriber
+// has disconnected), re-deriving the augmented Deployment view from the
+// shared pod/event caches on every delta and broadcasting it to all current
+// subscribers. A closed watch channel or a "resource version too old" error
+// is handled by transparently re-listing and resuming from the fresh
+// resourceVersion.
+func (h *deploymentWatchHub) run(client client.Interface, nsQuery *common.NamespaceQuery, ns string) {
+	resourceVersion := ""
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		default:
+		}
+
+		if resourceVersion == "" {
+			list, err := client.Extensions().Deployments(ns).List(api.ListOptions{})
+			if err != nil {
+				log.Printf("Error listing deployments for watch in namespace %s: %v", ns, err)
+				if !h.sleepOrStop(watchRetryBackoff) {
+					return
+				}
+				continue
+			}
+			resourceVersion = list.ResourceVersion
+		}
+
+		watcher, err := client.Extensions().Deployments(ns).Watch(api.ListOptions{ResourceVersion: resourceVersion})
+		if err != nil {
+			log.Printf("Error opening deployment watch in namespace %s: %v", ns, err)
+			resourceVersion = ""
+			if !h.sleepOrStop(watchRetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		if h.drainWatch(client, nsQuery, watcher) {
+			return
+		}
+		resourceVersion = ""
+	}
+}
+
+// sleepOrStop waits for d, returning true, unless h.stopCh closes first, in
+// which case it returns false immediately without waiting out the rest of d.
+func (h *deploymentWatchHub) sleepOrStop(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-h.stopCh:
+		return false
+	}
+}
+
+// drainWatch broadcasts every event read off watcher until it is closed,
+// reports a watch.Error, or h.stopCh closes, Stop()ing watcher before
+// returning in every case so the underlying watch connection and goroutine
+// are not leaked on every relist. Every exit forces run to relist from
+// scratch, so it reports only whether h.stopCh had closed - run should
+// return immediately rather than relist when it has.
+func (h *deploymentWatchHub) drainWatch(client client.Interface, nsQuery *common.NamespaceQuery,
+	watcher watch.Interface) bool {
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return true
+		case watchEvent, ok := <-watcher.ResultChan():
+			if !ok {
+				// watcher.ResultChan() closed - relist and re-watch from scratch.
+				return false
+			}
+			if watchEvent.Type == watch.Error {
+				// Most commonly a "too old resource version" - relist from scratch.
+				return false
+			}
+
+			d, ok := watchEvent.Object.(*extensions.Deployment)
+			if !ok {
+				continue
+			}
+
+			h.broadcast(DeploymentWatchEvent{
+				Type:   watchEvent.Type,
+				Object: h.composeDeployment(client, nsQuery, d),
+			})
+		}
+	}
+}
+
+// composeDeployment re-derives the augmented Deployment view for d, reading
+// matching pods and warning events out of the shared caches kept warm by
+// getPodStore/getEventStore rather than issuing fresh apiserver calls.
+func (h *deploymentWatchHub) composeDeployment(client client.Interface, nsQuery *common.NamespaceQuery,
+	d *extensions.Deployment) *Deployment {
+
+	rawPods, err := getPodStore(client, nsQuery).List()
+	if err != nil {
+		log.Printf("Error reading pod cache while composing deployment watch event: %v", err)
+	}
+	rawEvents, err := getEventStore(client, nsQuery).List()
+	if err != nil {
+		log.Printf("Error reading event cache while composing deployment watch event: %v", err)
+	}
+
+	matchingPods := common.FilterNamespacedPodsBySelector(common.ToPods(rawPods), d.ObjectMeta.Namespace,
+		d.Spec.Selector.MatchLabels)
+	podInfo := common.GetPodInfo(d.Status.Replicas, d.Spec.Replicas, matchingPods)
+	podInfo.Warnings = event.GetPodsEventWarnings(common.ToEvents(rawEvents), matchingPods)
+
+	return &Deployment{
+		ObjectMeta:      common.NewObjectMeta(d.ObjectMeta),
+		TypeMeta:        common.NewTypeMeta(common.ResourceKindDeployment),
+		ContainerImages: common.GetContainerImages(&d.Spec.Template.Spec),
+		Pods:            podInfo,
+		Status:          deploymentStatus(client, d, nsQuery),
+	}
+}
+
+// WatchDeploymentList subscribes to incremental changes to the DeploymentList
+// for nsQuery and writes each one to w as a Server-Sent Event, until the
+// client disconnects. Concurrent callers for the same nsQuery share a single
+// underlying apiserver watch via the package's deploymentWatchHub, so N
+// browsers watching the same scope do not open N watches.
+func WatchDeploymentList(w http.ResponseWriter, r *http.Request, client client.Interface,
+	nsQuery *common.NamespaceQuery) error {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming unsupported by the underlying ResponseWriter")
+	}
+	closeNotifier, ok := w.(http.CloseNotifier)
+	if !ok {
+		return errors.New("close notification unsupported by the underlying ResponseWriter")
+	}
+
+	hub, subscriber := subscribeToWatchHub(client, nsQuery)
+	defer unsubscribeFromWatchHub(client, nsQuery.ToRequestParam(), hub, subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	for {
+		select {
+		case deploymentEvent, ok := <-subscriber:
+			if !ok {
+				return nil
+			}
+			payload, err := json.Marshal(deploymentEvent)
+			if err != nil {
+				log.Printf("Error marshalling deployment watch event: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", deploymentEvent.Type, payload); err != nil {
+				return err
+			}
+			flusher.Flush()
+		case <-closeNotifier.CloseNotify():
+			return nil
+		}
+	}
+}
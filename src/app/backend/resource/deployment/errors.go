@@ -0,0 +1,73 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	k8serrors "k8s.io/kubernetes/pkg/api/errors"
+)
+
+// ErrorReason enumerates the broad categories of error the Deployment CRUD
+// operations in this package can surface. The frontend switches on Reason
+// rather than parsing Message, so a 409 Conflict can be retried while a 422
+// Invalid cannot.
+type ErrorReason string
+
+const (
+	// ErrorReasonNotFound means the Deployment being operated on does not exist.
+	ErrorReasonNotFound ErrorReason = "NotFound"
+	// ErrorReasonConflict means the operation raced a concurrent update, usually
+	// a stale ResourceVersion on Update.
+	ErrorReasonConflict ErrorReason = "Conflict"
+	// ErrorReasonInvalid means the apiserver rejected the Deployment spec itself.
+	ErrorReasonInvalid ErrorReason = "Invalid"
+)
+
+// Error is a typed error returned by this package's CRUD operations so that
+// callers - in particular the HTTP handler layer - can map it to the right
+// status code without string-matching a Kubernetes status message.
+type Error struct {
+	Reason  ErrorReason
+	Message string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// toDeploymentError maps a Kubernetes API error into the typed Error this
+// package's CRUD operations return. Errors that are not a recognized
+// *k8serrors.StatusError reason are returned unchanged.
+func toDeploymentError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	statusErr, ok := err.(*k8serrors.StatusError)
+	if !ok {
+		return err
+	}
+
+	switch statusErr.ErrStatus.Reason {
+	case "NotFound":
+		return &Error{Reason: ErrorReasonNotFound, Message: statusErr.ErrStatus.Message}
+	case "Conflict":
+		return &Error{Reason: ErrorReasonConflict, Message: statusErr.ErrStatus.Message}
+	case "Invalid":
+		return &Error{Reason: ErrorReasonInvalid, Message: statusErr.ErrStatus.Message}
+	default:
+		return err
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"errors"
+	"testing"
+
+	k8serrors "k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+)
+
+func TestToDeploymentError(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        error
+		wantReason ErrorReason
+		wantTyped  bool
+	}{
+		{
+			name:       "NotFound maps to ErrorReasonNotFound",
+			err:        statusError("NotFound", "deployments.extensions \"foo\" not found"),
+			wantReason: ErrorReasonNotFound,
+			wantTyped:  true,
+		},
+		{
+			name:       "Conflict maps to ErrorReasonConflict",
+			err:        statusError("Conflict", "the object has been modified"),
+			wantReason: ErrorReasonConflict,
+			wantTyped:  true,
+		},
+		{
+			name:       "Invalid maps to ErrorReasonInvalid",
+			err:        statusError("Invalid", "Deployment.extensions \"foo\" is invalid"),
+			wantReason: ErrorReasonInvalid,
+			wantTyped:  true,
+		},
+		{
+			name:      "unrecognized StatusError reason is returned unchanged",
+			err:       statusError("ServerTimeout", "etcd is unavailable"),
+			wantTyped: false,
+		},
+		{
+			name:      "non-StatusError is returned unchanged",
+			err:       errors.New("boom"),
+			wantTyped: false,
+		},
+		{
+			name: "nil stays nil",
+			err:  nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := toDeploymentError(c.err)
+
+			if c.err == nil {
+				if got != nil {
+					t.Fatalf("toDeploymentError(nil) = %v, want nil", got)
+				}
+				return
+			}
+
+			typed, ok := got.(*Error)
+			if ok != c.wantTyped {
+				t.Fatalf("toDeploymentError(%v) typed = %v, want %v", c.err, ok, c.wantTyped)
+			}
+			if !ok {
+				if got != c.err {
+					t.Fatalf("toDeploymentError(%v) = %v, want unchanged", c.err, got)
+				}
+				return
+			}
+			if typed.Reason != c.wantReason {
+				t.Errorf("Reason = %q, want %q", typed.Reason, c.wantReason)
+			}
+		})
+	}
+}
+
+// statusError builds a *k8serrors.StatusError with the given reason and
+// message, the shape toDeploymentError switches on.
+func statusError(reason unversioned.StatusReason, message string) *k8serrors.StatusError {
+	return &k8serrors.StatusError{
+		ErrStatus: unversioned.Status{
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+	"github.com/kubernetes/dashboard/src/app/backend/resource/event"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// kindHandler is this package's common.ResourceKindHandler, registered in
+// init() so the generic /resourcekind endpoint can dispatch to Deployments
+// the same way it dispatches to any other registered kind, instead of
+// deploymentlist.go being special-cased by the list endpoint.
+type kindHandler struct{}
+
+func (kindHandler) Kind() common.ResourceKind {
+	return common.ResourceKindDeployment
+}
+
+func (kindHandler) ListChannel(client client.Interface, nsQuery *common.NamespaceQuery) *common.ResourceStore {
+	return getDeploymentStore(client, nsQuery)
+}
+
+// Convert implements common.ResourceKindHandler, reusing the same pod/event
+// aggregation CreateDeploymentList uses so the generic registry path and the
+// dedicated /deployment endpoints stay in sync.
+func (kindHandler) Convert(raw []interface{}, pods []api.Pod, events []api.Event,
+	dsQuery *common.DataSelectQuery) []common.CommonListItem {
+
+	deployments := fromCells(common.GenericDataSelect(toCells(toDeployments(raw)), dsQuery))
+	podIndex := common.NewPodsByNamespace(pods)
+
+	items := make([]common.CommonListItem, 0, len(deployments))
+	for _, deployment := range deployments {
+		matchingPods := common.FilterNamespacedPodsBySelector(podIndex.Get(deployment.ObjectMeta.Namespace),
+			deployment.ObjectMeta.Namespace, deployment.Spec.Selector.MatchLabels)
+		podInfo := common.GetPodInfo(deployment.Status.Replicas, deployment.Spec.Replicas, matchingPods)
+		podInfo.Warnings = event.GetPodsEventWarnings(events, matchingPods)
+
+		items = append(items, common.CommonListItem{
+			ObjectMeta:      common.NewObjectMeta(deployment.ObjectMeta),
+			TypeMeta:        common.NewTypeMeta(common.ResourceKindDeployment),
+			Pods:            podInfo,
+			ContainerImages: common.GetContainerImages(&deployment.Spec.Template.Spec),
+		})
+	}
+	return items
+}
+
+func init() {
+	common.RegisterKind(kindHandler{})
+}
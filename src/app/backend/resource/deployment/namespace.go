@@ -0,0 +1,39 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// ValidateNamespace returns an error unless namespace is empty (meaning "all
+// namespaces") or names a Namespace that actually exists. CacheMetrics keys
+// permanently running cache stores off namespace, and WatchDeploymentList
+// keys a watch hub that runs until its last subscriber leaves; namespace is
+// attacker-controlled HTTP path input, so without this check, requesting a
+// distinct nonexistent namespace string on every call would start more
+// goroutines and apiserver watches per string (for the life of the process,
+// in the CacheMetrics case). Callers should run this before building a
+// NamespaceQuery for either of those two entry points.
+func ValidateNamespace(client client.Interface, namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	if _, err := client.Namespaces().Get(namespace); err != nil {
+		return toDeploymentError(err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	fake := testclient.NewSimpleFake(&api.Namespace{
+		ObjectMeta: api.ObjectMeta{Name: "default"},
+	})
+
+	if err := ValidateNamespace(fake, ""); err != nil {
+		t.Errorf("ValidateNamespace(\"\") = %v, want nil for the all-namespaces case", err)
+	}
+
+	if err := ValidateNamespace(fake, "default"); err != nil {
+		t.Errorf("ValidateNamespace(\"default\") = %v, want nil for an existing namespace", err)
+	}
+
+	if err := ValidateNamespace(fake, "does-not-exist"); err == nil {
+		t.Error("ValidateNamespace(\"does-not-exist\") = nil, want an error")
+	}
+}
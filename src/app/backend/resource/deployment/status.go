@@ -0,0 +1,192 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/kubernetes/dashboard/src/app/backend/resource/common"
+
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/labels"
+)
+
+// StatusType is the outcome of GetDeploymentStatus's readiness evaluation.
+// common.GetPodInfo only tallies replica counts, which misleadingly reports a
+// Deployment as healthy while its rollout is stuck (ImagePullBackOff,
+// progress deadline exceeded, unavailable replicas, old ReplicaSets still
+// lingering); StatusType distinguishes those cases explicitly.
+type StatusType string
+
+const (
+	// StatusReady means the rollout has fully completed: every desired replica
+	// of the new ReplicaSet is updated and available, and no old ReplicaSet
+	// still has running replicas.
+	StatusReady StatusType = "Ready"
+	// StatusProgressing means the rollout is still under way, within its
+	// progress deadline.
+	StatusProgressing StatusType = "Progressing"
+	// StatusFailed means the rollout's progress deadline has been exceeded.
+	StatusFailed StatusType = "Failed"
+	// StatusPaused means the Deployment's rollout has been explicitly paused
+	// via spec.paused and is not currently progressing either way.
+	StatusPaused StatusType = "Paused"
+)
+
+// progressDeadlineExceededReason is the Progressing condition Reason the
+// deployment controller sets once spec.progressDeadlineSeconds has elapsed
+// without forward progress.
+const progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+
+// newReplicaSetAvailableReason is the Progressing condition Reason the
+// deployment controller sets once the new ReplicaSet has become fully
+// available.
+const newReplicaSetAvailableReason = "NewReplicaSetAvailable"
+
+// progressingConditionType mirrors extensions.DeploymentProgressing, spelled
+// out as a string literal to avoid depending on the precise constant name
+// across API versions.
+const progressingConditionType = "Progressing"
+
+// DeploymentStatus is the presentation layer view of a Deployment's rollout
+// readiness, computed with Helm 3's resource readiness algorithm for
+// Deployments rather than Kubernetes' own (looser) "available" notion.
+type DeploymentStatus struct {
+	Status StatusType `json:"status"`
+	// Reason is a short, human-readable explanation of Status, suitable for
+	// display next to a rollout badge in the UI.
+	Reason string `json:"reason"`
+}
+
+// GetDeploymentStatus classifies the rollout state of deployment as Ready,
+// Progressing, Failed or Paused, using Helm 3's Deployment readiness rules:
+// a Deployment is Ready iff status.observedGeneration >= metadata.generation,
+// its Progressing condition has reason NewReplicaSetAvailable (not
+// ProgressDeadlineExceeded), status.updatedReplicas == spec.replicas,
+// status.availableReplicas == spec.replicas, and no ReplicaSet in
+// oldReplicaSets still has status.replicas > 0.
+func GetDeploymentStatus(deployment *extensions.Deployment, oldReplicaSets []extensions.ReplicaSet) DeploymentStatus {
+	if deployment.Spec.Paused {
+		return DeploymentStatus{Status: StatusPaused, Reason: "Deployment rollout is paused"}
+	}
+
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return DeploymentStatus{
+			Status: StatusProgressing,
+			Reason: "Waiting for the deployment controller to observe the latest spec",
+		}
+	}
+
+	progressing := getProgressingCondition(deployment)
+	if progressing != nil && progressing.Reason == progressDeadlineExceededReason {
+		return DeploymentStatus{
+			Status: StatusFailed,
+			Reason: fmt.Sprintf("Deployment %q exceeded its progress deadline", deployment.Name),
+		}
+	}
+
+	desiredReplicas := deployment.Spec.Replicas
+
+	if progressing == nil || progressing.Reason != newReplicaSetAvailableReason {
+		return DeploymentStatus{
+			Status: StatusProgressing,
+			Reason: "Waiting for the new ReplicaSet to become available",
+		}
+	}
+
+	if deployment.Status.UpdatedReplicas != desiredReplicas {
+		return DeploymentStatus{
+			Status: StatusProgressing,
+			Reason: fmt.Sprintf("Waiting for rollout: %d of %d new replicas have been updated",
+				deployment.Status.UpdatedReplicas, desiredReplicas),
+		}
+	}
+
+	if deployment.Status.AvailableReplicas != desiredReplicas {
+		return DeploymentStatus{
+			Status: StatusProgressing,
+			Reason: fmt.Sprintf("Waiting for rollout: %d of %d updated replicas are available",
+				deployment.Status.AvailableReplicas, desiredReplicas),
+		}
+	}
+
+	for _, old := range oldReplicaSets {
+		if old.Status.Replicas > 0 {
+			return DeploymentStatus{
+				Status: StatusProgressing,
+				Reason: fmt.Sprintf("Waiting for old ReplicaSet %q to scale down", old.Name),
+			}
+		}
+	}
+
+	return DeploymentStatus{Status: StatusReady, Reason: "Deployment rollout has completed"}
+}
+
+// deploymentStatus computes GetDeploymentStatus for deployment, reading its
+// candidate old ReplicaSets from the shared ReplicaSet cache for nsQuery
+// instead of fetching them fresh, so every presentation-layer view of a
+// Deployment - list, CRUD response, watch event - reports the same rollout
+// status.
+func deploymentStatus(client client.Interface, deployment *extensions.Deployment,
+	nsQuery *common.NamespaceQuery) DeploymentStatus {
+
+	rawReplicaSets, err := getReplicaSetStore(client, nsQuery).List()
+	if err != nil {
+		log.Printf("Error reading replica set cache while computing deployment status: %v", err)
+		return GetDeploymentStatus(deployment, nil)
+	}
+
+	return GetDeploymentStatus(deployment, oldReplicaSetsFor(deployment, toReplicaSets(rawReplicaSets)))
+}
+
+// oldReplicaSetsFor returns the ReplicaSets in replicaSets owned by
+// deployment (matched by its selector) that are not the "new" ReplicaSet -
+// the one whose Pod template already matches the Deployment's current
+// template. It is a simplified stand-in for the deployment controller's own
+// new/old ReplicaSet bookkeeping, good enough to tell GetDeploymentStatus
+// whether an old ReplicaSet is still lingering with live replicas.
+func oldReplicaSetsFor(deployment *extensions.Deployment, replicaSets []extensions.ReplicaSet) []extensions.ReplicaSet {
+	selector := labels.Set(deployment.Spec.Selector.MatchLabels).AsSelector()
+
+	old := make([]extensions.ReplicaSet, 0)
+	for _, rs := range replicaSets {
+		if rs.ObjectMeta.Namespace != deployment.ObjectMeta.Namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(rs.ObjectMeta.Labels)) {
+			continue
+		}
+		if reflect.DeepEqual(rs.Spec.Template.Spec, deployment.Spec.Template.Spec) {
+			continue
+		}
+		old = append(old, rs)
+	}
+	return old
+}
+
+// getProgressingCondition returns the Progressing DeploymentCondition for
+// deployment, or nil if the controller has not reported one yet.
+func getProgressingCondition(deployment *extensions.Deployment) *extensions.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		condition := &deployment.Status.Conditions[i]
+		if string(condition.Type) == progressingConditionType {
+			return condition
+		}
+	}
+	return nil
+}
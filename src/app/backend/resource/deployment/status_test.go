@@ -0,0 +1,195 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+)
+
+// settledDeployment returns a Deployment whose rollout has fully completed -
+// every case below starts from this and changes only the fields its name
+// describes.
+func settledDeployment() *extensions.Deployment {
+	return &extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{Name: "frontend", Generation: 2},
+		Spec: extensions.DeploymentSpec{
+			Replicas: 3,
+		},
+		Status: extensions.DeploymentStatus{
+			ObservedGeneration: 2,
+			UpdatedReplicas:    3,
+			AvailableReplicas:  3,
+			Conditions: []extensions.DeploymentCondition{
+				{Type: progressingConditionType, Reason: newReplicaSetAvailableReason},
+			},
+		},
+	}
+}
+
+func TestGetDeploymentStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		deployment func() *extensions.Deployment
+		oldRS      []extensions.ReplicaSet
+		want       StatusType
+	}{
+		{
+			name: "paused deployment reports Paused regardless of status",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Spec.Paused = true
+				return d
+			},
+			want: StatusPaused,
+		},
+		{
+			name: "progress deadline exceeded reports Failed",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Status.Conditions = []extensions.DeploymentCondition{
+					{Type: progressingConditionType, Reason: progressDeadlineExceededReason},
+				}
+				return d
+			},
+			want: StatusFailed,
+		},
+		{
+			name: "stale observed generation reports Progressing",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Status.ObservedGeneration = 1
+				return d
+			},
+			want: StatusProgressing,
+		},
+		{
+			name: "stale observed generation reports Progressing even with a leftover ProgressDeadlineExceeded condition from a previous generation's failed rollout",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Status.ObservedGeneration = 1
+				d.Status.Conditions = []extensions.DeploymentCondition{
+					{Type: progressingConditionType, Reason: progressDeadlineExceededReason},
+				}
+				return d
+			},
+			want: StatusProgressing,
+		},
+		{
+			name: "scaled to zero and settled at zero reports Ready",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Spec.Replicas = 0
+				d.Status.UpdatedReplicas = 0
+				d.Status.AvailableReplicas = 0
+				return d
+			},
+			want: StatusReady,
+		},
+		{
+			name: "updated replicas behind desired reports Progressing",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Status.UpdatedReplicas = 1
+				return d
+			},
+			want: StatusProgressing,
+		},
+		{
+			name: "available replicas behind desired reports Progressing",
+			deployment: func() *extensions.Deployment {
+				d := settledDeployment()
+				d.Status.AvailableReplicas = 1
+				return d
+			},
+			want: StatusProgressing,
+		},
+		{
+			name:       "lingering old ReplicaSet reports Progressing",
+			deployment: settledDeployment,
+			oldRS: []extensions.ReplicaSet{
+				{
+					ObjectMeta: api.ObjectMeta{Name: "frontend-old"},
+					Status:     extensions.ReplicaSetStatus{Replicas: 1},
+				},
+			},
+			want: StatusProgressing,
+		},
+		{
+			name:       "fully settled deployment with no lingering old ReplicaSets reports Ready",
+			deployment: settledDeployment,
+			want:       StatusReady,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := GetDeploymentStatus(c.deployment(), c.oldRS)
+			if got.Status != c.want {
+				t.Errorf("Status = %q, want %q (reason: %q)", got.Status, c.want, got.Reason)
+			}
+		})
+	}
+}
+
+func TestOldReplicaSetsFor(t *testing.T) {
+	newTemplateSpec := api.PodSpec{Containers: []api.Container{{Image: "frontend:v2"}}}
+	oldTemplateSpec := api.PodSpec{Containers: []api.Container{{Image: "frontend:v1"}}}
+
+	deployment := &extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{Namespace: "default"},
+		Spec: extensions.DeploymentSpec{
+			Selector: &unversioned.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}},
+			Template: api.PodTemplateSpec{Spec: newTemplateSpec},
+		},
+	}
+
+	replicaSets := []extensions.ReplicaSet{
+		{
+			// Matches the selector and predates the current template - old.
+			ObjectMeta: api.ObjectMeta{Name: "frontend-v1", Namespace: "default",
+				Labels: map[string]string{"app": "frontend"}},
+			Spec: extensions.ReplicaSetSpec{Template: api.PodTemplateSpec{Spec: oldTemplateSpec}},
+		},
+		{
+			// Matches the selector but is the current template - the "new" set,
+			// not an old one.
+			ObjectMeta: api.ObjectMeta{Name: "frontend-v2", Namespace: "default",
+				Labels: map[string]string{"app": "frontend"}},
+			Spec: extensions.ReplicaSetSpec{Template: api.PodTemplateSpec{Spec: newTemplateSpec}},
+		},
+		{
+			// Old template, but in a different namespace.
+			ObjectMeta: api.ObjectMeta{Name: "other-ns-v1", Namespace: "other",
+				Labels: map[string]string{"app": "frontend"}},
+			Spec: extensions.ReplicaSetSpec{Template: api.PodTemplateSpec{Spec: oldTemplateSpec}},
+		},
+		{
+			// Old template, but the Deployment's selector does not match its labels.
+			ObjectMeta: api.ObjectMeta{Name: "unrelated", Namespace: "default",
+				Labels: map[string]string{"app": "backend"}},
+			Spec: extensions.ReplicaSetSpec{Template: api.PodTemplateSpec{Spec: oldTemplateSpec}},
+		},
+	}
+
+	old := oldReplicaSetsFor(deployment, replicaSets)
+
+	if len(old) != 1 || old[0].Name != "frontend-v1" {
+		t.Fatalf("oldReplicaSetsFor() = %v, want only frontend-v1", old)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 Google Inc. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deployment
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	client "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// statusPollInterval is how often WaitForDeploymentStatus re-checks the
+// Deployment's rollout status while waiting for it to settle.
+const statusPollInterval = 2 * time.Second
+
+// WaitForDeploymentStatus polls the Deployment named name in namespace, along
+// with its ReplicaSets, until GetDeploymentStatus reports StatusReady or
+// StatusFailed, or until timeout elapses - whichever comes first. It backs
+// the /deployment/{namespace}/{name}/status endpoint used for CI gating and
+// for the UI to render an accurate rollout badge without polling itself.
+func WaitForDeploymentStatus(client client.Interface, namespace, name string,
+	timeout time.Duration) (DeploymentStatus, error) {
+
+	deadline := time.Now().Add(timeout)
+	for {
+		deployment, err := client.Extensions().Deployments(namespace).Get(name)
+		if err != nil {
+			return DeploymentStatus{}, toDeploymentError(err)
+		}
+
+		rsList, err := client.Extensions().ReplicaSets(namespace).List(api.ListOptions{})
+		if err != nil {
+			return DeploymentStatus{}, toDeploymentError(err)
+		}
+
+		status := GetDeploymentStatus(deployment, oldReplicaSetsFor(deployment, rsList.Items))
+		if status.Status == StatusReady || status.Status == StatusFailed {
+			return status, nil
+		}
+
+		if time.Now().After(deadline) {
+			return status, nil
+		}
+		time.Sleep(statusPollInterval)
+	}
+}